@@ -0,0 +1,27 @@
+package distribution
+
+import "context"
+
+// ManifestBuilder creates a manifest allowing one to include dependencies.
+// Instances can be obtained from a version-specific manifest package.
+// Manifest specific data is passed into the function which creates the
+// builder.
+type ManifestBuilder interface {
+	// Build creates the manifest from this builder.
+	Build(ctx context.Context) (Manifest, error)
+
+	// References returns a list of objects which have been added to this
+	// builder. The dependencies are returned in the order they were
+	// added, which should be from base to head.
+	References() []Descriptor
+
+	// AppendReference includes the given object in the manifest after any
+	// existing dependencies. If the add fails, such as when adding an
+	// unsupported dependency, an error is returned.
+	AppendReference(dependency Describable) error
+
+	// SetAnnotations sets the manifest-level annotations on the builder,
+	// replacing any that were previously set. Manifest formats that do
+	// not support annotations may ignore this call.
+	SetAnnotations(annotations map[string]string)
+}
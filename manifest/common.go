@@ -0,0 +1,67 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// canonicalOptions holds the configurable bits of CanonicalJSON's output.
+type canonicalOptions struct {
+	indent string
+}
+
+// CanonicalOption configures the output of CanonicalJSON.
+type CanonicalOption func(*canonicalOptions)
+
+// WithIndent overrides the indentation CanonicalJSON uses for its output.
+// It exists for callers that need byte-for-byte compatibility with the
+// three-space indentation that FromStruct historically produced.
+func WithIndent(indent string) CanonicalOption {
+	return func(o *canonicalOptions) {
+		o.indent = indent
+	}
+}
+
+// htmlEscapes lists the unicode escapes encoding/json substitutes for '<',
+// '>' and '&' inside string literals. They're turned back into literal
+// characters after indenting. Escaped backslashes are set aside first, so
+// that a string holding a literal backslash followed by "u003c" is left as
+// it is.
+var htmlEscapes = [...][2]string{
+	{"\\u003c", "<"},
+	{"\\u003e", ">"},
+	{"\\u0026", "&"},
+}
+
+// CanonicalJSON re-indents the JSON document in b into this package's
+// canonical form: a fixed two-space indent and no HTML escaping. It is
+// purely a formatting pass — it does not decode b into a Go value, so
+// object member order (struct field order, as emitted by json.Marshal)
+// and number precision are preserved exactly as they appear in b. The one
+// exception is map[string]string fields such as annotations, whose key
+// order encoding/json already makes deterministic (lexical) at marshal
+// time, before the bytes ever reach this function.
+//
+// The indentation can be overridden with WithIndent, for callers that need
+// to reproduce output produced before this helper existed.
+func CanonicalJSON(b []byte, options ...CanonicalOption) ([]byte, error) {
+	opts := canonicalOptions{indent: "  "}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", opts.indent); err != nil {
+		return nil, err
+	}
+
+	// A NUL byte never appears in JSON that json.Indent accepted, so it can
+	// stand in for each escaped backslash while the escapes are replaced.
+	out := bytes.ReplaceAll(buf.Bytes(), []byte(`\\`), []byte{0})
+	for _, escape := range htmlEscapes {
+		out = bytes.ReplaceAll(out, []byte(escape[0]), []byte(escape[1]))
+	}
+	out = bytes.ReplaceAll(out, []byte{0}, []byte(`\\`))
+
+	return out, nil
+}
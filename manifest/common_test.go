@@ -0,0 +1,60 @@
+package manifest
+
+import "testing"
+
+func TestCanonicalJSONPreservesFieldOrder(t *testing.T) {
+	const in = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:abc","size":123},"layers":[]}`
+
+	out, err := CanonicalJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("CanonicalJSON returned error: %v", err)
+	}
+
+	const want = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {
+    "mediaType": "application/vnd.oci.image.config.v1+json",
+    "digest": "sha256:abc",
+    "size": 123
+  },
+  "layers": []
+}`
+	if string(out) != want {
+		t.Fatalf("CanonicalJSON reordered object keys:\ngot:  %s\nwant: %s", out, want)
+	}
+}
+
+func TestCanonicalJSONPreservesLargeIntegers(t *testing.T) {
+	const in = `{"size":9223372036854775000,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`
+
+	out, err := CanonicalJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("CanonicalJSON returned error: %v", err)
+	}
+
+	const want = `{
+  "size": 9223372036854775000,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json"
+}`
+	if string(out) != want {
+		t.Fatalf("CanonicalJSON altered a large integer:\ngot:  %s\nwant: %s", out, want)
+	}
+}
+
+func TestCanonicalJSONDisablesHTMLEscaping(t *testing.T) {
+	// Simulates what the default json.Marshal (as used upstream in
+	// FromStruct) would have produced for a value containing HTML-special
+	// characters: the three characters are unicode-escaped.
+	const in = "{\"annotations\":{\"a\":\"\\u003cb\\u003e\\u0026c\\u003c/b\\u003e\"}}"
+
+	out, err := CanonicalJSON([]byte(in))
+	if err != nil {
+		t.Fatalf("CanonicalJSON returned error: %v", err)
+	}
+
+	const want = "{\n  \"annotations\": {\n    \"a\": \"<b>&c</b>\"\n  }\n}"
+	if string(out) != want {
+		t.Fatalf("CanonicalJSON left HTML characters escaped:\ngot:  %s\nwant: %s", out, want)
+	}
+}
@@ -0,0 +1,237 @@
+package manifestlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest"
+)
+
+const (
+	// MediaTypeManifestList specifies the mediaType for manifest lists.
+	MediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// MediaTypeImageIndex specifies the mediaType for OCI image indexes.
+	MediaTypeImageIndex = "application/vnd.oci.image.index.v1+json"
+)
+
+var (
+	// SchemaVersion provides a pre-initialized version structure for this
+	// packages Docker manifest list.
+	SchemaVersion = manifest.Versioned{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifestList,
+	}
+
+	// OCISchemaVersion provides a pre-initialized version structure for
+	// this packages OCI image index.
+	OCISchemaVersion = manifest.Versioned{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+	}
+)
+
+func init() {
+	// unmarshalFunc returns a schema func that only accepts payloads whose
+	// own mediaType (when set) agrees with the mediaType it was
+	// registered under, so a Docker manifest list served under the OCI
+	// image index registration (or vice versa) is rejected.
+	unmarshalFunc := func(expectedMediaType string) func(b []byte) (distribution.Manifest, distribution.Descriptor, error) {
+		return func(b []byte) (distribution.Manifest, distribution.Descriptor, error) {
+			m := new(DeserializedManifestList)
+			if err := m.unmarshalJSON(b, expectedMediaType); err != nil {
+				return nil, distribution.Descriptor{}, err
+			}
+
+			dgst := digest.FromBytes(b)
+			return m, distribution.Descriptor{Digest: dgst, Size: int64(len(b)), MediaType: expectedMediaType}, nil
+		}
+	}
+
+	err := distribution.RegisterManifestSchema(MediaTypeManifestList, unmarshalFunc(MediaTypeManifestList))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to register manifest: %s", err))
+	}
+
+	err = distribution.RegisterManifestSchema(MediaTypeImageIndex, unmarshalFunc(MediaTypeImageIndex))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to register manifest: %s", err))
+	}
+}
+
+// ErrManifestBadMediaType is returned when the media type in the manifest
+// list itself is neither the Docker manifest list type nor the OCI image
+// index type. Per the OCI spec, an empty mediaType is also accepted.
+type ErrManifestBadMediaType struct {
+	MediaType string
+}
+
+func (err ErrManifestBadMediaType) Error() string {
+	return fmt.Sprintf("manifest list had unexpected media type: %q", err.MediaType)
+}
+
+// PlatformSpec specifies the platform which the referenced image manifest
+// runs on.
+type PlatformSpec struct {
+	// Architecture field specifies the CPU architecture, for example
+	// `amd64` or `ppc64`.
+	Architecture string `json:"architecture"`
+
+	// OS specifies the operating system, for example `linux` or `windows`.
+	OS string `json:"os"`
+
+	// OSVersion is an optional field specifying the operating system
+	// version, for example `10.0.10586`.
+	OSVersion string `json:"os.version,omitempty"`
+
+	// OSFeatures is an optional field specifying an array of strings,
+	// each listing a required OS feature (for example on Windows `win32k`).
+	OSFeatures []string `json:"os.features,omitempty"`
+
+	// Variant is an optional field specifying a variant of the CPU, for
+	// example `v7` to specify ARMv7 when architecture is `arm`.
+	Variant string `json:"variant,omitempty"`
+
+	// Features is an optional field specifying an array of strings, each
+	// listing a required CPU feature (for example `sse4` or `aes`).
+	Features []string `json:"features,omitempty"`
+}
+
+// ManifestDescriptor references a platform-specific manifest.
+type ManifestDescriptor struct {
+	distribution.Descriptor
+
+	// Platform describes the platform which the image in the manifest
+	// runs on. OCI image indexes may omit this for entries that don't
+	// describe a runnable image, such as attestations or SBOMs.
+	Platform *PlatformSpec `json:"platform,omitempty"`
+
+	// ArtifactType identifies the type of an artifact referenced by this
+	// entry when it does not carry a platform, such as the artifactType
+	// of a referenced attestation manifest. This is an OCI-only field.
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// ManifestList references manifests for various platforms.
+type ManifestList struct {
+	manifest.Versioned
+
+	// Manifests references a list of manifests
+	Manifests []ManifestDescriptor `json:"manifests"`
+}
+
+// References returns the distribution descriptors for the referenced
+// manifests.
+func (m ManifestList) References() []distribution.Descriptor {
+	dependencies := make([]distribution.Descriptor, len(m.Manifests))
+	for i, descriptor := range m.Manifests {
+		dependencies[i] = descriptor.Descriptor
+	}
+	return dependencies
+}
+
+// DeserializedManifestList wraps ManifestList with a copy of the original
+// JSON. It satisfies the distribution.Manifest interface.
+type DeserializedManifestList struct {
+	ManifestList
+
+	// canonical is the canonical byte representation of the ManifestList.
+	canonical []byte
+}
+
+// FromDescriptors takes a slice of descriptors and returns a
+// DeserializedManifestList which contains the resulting Docker manifest
+// list.
+func FromDescriptors(descriptors []ManifestDescriptor) (*DeserializedManifestList, error) {
+	return FromDescriptorsWithMediaType(descriptors, MediaTypeManifestList)
+}
+
+// FromDescriptorsWithMediaType is like FromDescriptors, but lets the caller
+// choose between the Docker manifest list media type and the OCI image
+// index media type for the resulting list.
+func FromDescriptorsWithMediaType(descriptors []ManifestDescriptor, mediaType string) (*DeserializedManifestList, error) {
+	versioned := SchemaVersion
+	if mediaType == MediaTypeImageIndex {
+		versioned = OCISchemaVersion
+	}
+	versioned.MediaType = mediaType
+
+	m := ManifestList{
+		Versioned: versioned,
+	}
+	m.Manifests = make([]ManifestDescriptor, len(descriptors))
+	copy(m.Manifests, descriptors)
+
+	var deserialized DeserializedManifestList
+	deserialized.ManifestList = m
+
+	raw, err := json.Marshal(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	deserialized.canonical, err = manifest.CanonicalJSON(raw)
+	return &deserialized, err
+}
+
+// UnmarshalJSON populates a new ManifestList struct from JSON data. It
+// accepts either the Docker manifest list or the OCI image index media
+// type (or no media type at all), since the caller has not committed to
+// one the way the schema funcs registered in init have.
+func (m *DeserializedManifestList) UnmarshalJSON(b []byte) error {
+	return m.unmarshalJSON(b, "")
+}
+
+// unmarshalJSON is UnmarshalJSON's implementation. When expectedMediaType
+// is non-empty, it additionally rejects a payload whose own mediaType
+// field disagrees with it, so that the schema func registered for one
+// media type doesn't silently accept the other.
+func (m *DeserializedManifestList) unmarshalJSON(b []byte, expectedMediaType string) error {
+	m.canonical = make([]byte, len(b), len(b))
+	// store manifest list in canonical
+	copy(m.canonical, b)
+
+	// Unmarshal canonical JSON into ManifestList object
+	var manifestList ManifestList
+	if err := json.Unmarshal(m.canonical, &manifestList); err != nil {
+		return err
+	}
+
+	switch manifestList.MediaType {
+	case "", MediaTypeManifestList, MediaTypeImageIndex:
+	default:
+		return ErrManifestBadMediaType{MediaType: manifestList.MediaType}
+	}
+
+	if expectedMediaType != "" && manifestList.MediaType != "" && manifestList.MediaType != expectedMediaType {
+		return ErrManifestBadMediaType{MediaType: manifestList.MediaType}
+	}
+
+	m.ManifestList = manifestList
+
+	return nil
+}
+
+// MarshalJSON returns the contents of canonical. If canonical is empty,
+// marshals the inner contents.
+func (m *DeserializedManifestList) MarshalJSON() ([]byte, error) {
+	if len(m.canonical) > 0 {
+		return m.canonical, nil
+	}
+
+	return nil, errors.New("JSON representation not initialized in DeserializedManifestList")
+}
+
+// Payload returns the mediaType the list was deserialized (or built) as,
+// along with the raw content of the list. The contents can be used to
+// calculate the content identifier.
+func (m DeserializedManifestList) Payload() (string, []byte, error) {
+	mediaType := m.MediaType
+	if mediaType == "" {
+		mediaType = MediaTypeManifestList
+	}
+	return mediaType, m.canonical, nil
+}
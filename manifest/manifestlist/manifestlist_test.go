@@ -0,0 +1,133 @@
+package manifestlist
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/distribution"
+)
+
+// ociIndexWithAttestation is a trimmed real-world OCI image index
+// containing one platform-specific image manifest and one attestation
+// manifest, which per the OCI spec has no `platform` field.
+const ociIndexWithAttestation = `{
+	"schemaVersion": 2,
+	"mediaType": "application/vnd.oci.image.index.v1+json",
+	"manifests": [
+		{
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"digest": "sha256:e258d248fda28b54c99d8f7b3fbbcaac34c3e4047d1f8c4a0a8c58eb3ca55b25",
+			"size": 1234,
+			"platform": {
+				"architecture": "amd64",
+				"os": "linux"
+			}
+		},
+		{
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"digest": "sha256:a1c2b3d4e5f678901234567890abcdef1234567890abcdef1234567890abcd",
+			"size": 567,
+			"artifactType": "application/vnd.in-toto+json",
+			"annotations": {
+				"vnd.docker.reference.type": "attestation-manifest",
+				"vnd.docker.reference.digest": "sha256:e258d248fda28b54c99d8f7b3fbbcaac34c3e4047d1f8c4a0a8c58eb3ca55b25"
+			}
+		}
+	]
+}`
+
+func TestUnmarshalOCIIndexWithAttestation(t *testing.T) {
+	var deserialized DeserializedManifestList
+	if err := deserialized.UnmarshalJSON([]byte(ociIndexWithAttestation)); err != nil {
+		t.Fatalf("error unmarshaling index: %v", err)
+	}
+
+	if deserialized.MediaType != MediaTypeImageIndex {
+		t.Fatalf("unexpected mediaType: %q", deserialized.MediaType)
+	}
+
+	if len(deserialized.Manifests) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(deserialized.Manifests))
+	}
+
+	image := deserialized.Manifests[0]
+	if image.Platform == nil || image.Platform.Architecture != "amd64" || image.Platform.OS != "linux" {
+		t.Fatalf("unexpected platform for image manifest: %+v", image.Platform)
+	}
+
+	attestation := deserialized.Manifests[1]
+	if attestation.Platform != nil {
+		t.Fatalf("expected no platform on attestation manifest, got %+v", attestation.Platform)
+	}
+	if attestation.ArtifactType != "application/vnd.in-toto+json" {
+		t.Fatalf("unexpected artifactType: %q", attestation.ArtifactType)
+	}
+	if attestation.Annotations["vnd.docker.reference.type"] != "attestation-manifest" {
+		t.Fatalf("unexpected annotations: %+v", attestation.Annotations)
+	}
+
+	mediaType, payload, err := deserialized.Payload()
+	if err != nil {
+		t.Fatalf("error getting payload: %v", err)
+	}
+	if mediaType != MediaTypeImageIndex {
+		t.Fatalf("unexpected payload mediaType: %q", mediaType)
+	}
+
+	var roundTripped ManifestList
+	if err := json.Unmarshal(payload, &roundTripped); err != nil {
+		t.Fatalf("error round-tripping payload: %v", err)
+	}
+	if len(roundTripped.Manifests) != 2 || roundTripped.Manifests[1].Platform != nil {
+		t.Fatalf("round trip lost attestation manifest shape: %+v", roundTripped.Manifests)
+	}
+}
+
+func TestFromDescriptorsWithMediaType(t *testing.T) {
+	descriptors := []ManifestDescriptor{
+		{
+			Platform: &PlatformSpec{
+				Architecture: "arm64",
+				OS:           "linux",
+			},
+		},
+		{
+			Descriptor: distribution.Descriptor{
+				Annotations: map[string]string{
+					"vnd.docker.reference.type": "attestation-manifest",
+				},
+			},
+			ArtifactType: "application/vnd.in-toto+json",
+		},
+	}
+
+	deserialized, err := FromDescriptorsWithMediaType(descriptors, MediaTypeImageIndex)
+	if err != nil {
+		t.Fatalf("error building image index: %v", err)
+	}
+
+	mediaType, _, err := deserialized.Payload()
+	if err != nil {
+		t.Fatalf("error getting payload: %v", err)
+	}
+	if mediaType != MediaTypeImageIndex {
+		t.Fatalf("unexpected payload mediaType: %q", mediaType)
+	}
+}
+
+func TestUnmarshalJSONRejectsMismatchedRegisteredMediaType(t *testing.T) {
+	const dockerList = `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+		"manifests": []
+	}`
+
+	var m DeserializedManifestList
+	if err := m.unmarshalJSON([]byte(dockerList), MediaTypeImageIndex); err == nil {
+		t.Fatal("expected error unmarshaling a Docker manifest list under the OCI image index registration")
+	}
+
+	if err := m.unmarshalJSON([]byte(dockerList), MediaTypeManifestList); err != nil {
+		t.Fatalf("unexpected error unmarshaling a Docker manifest list under its own registration: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package ocischema
+
+import (
+	"context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+)
+
+// builder is a type for constructing manifests.
+type builder struct {
+	// bs is a BlobService used to get the blob size info.
+	bs distribution.BlobService
+
+	// configMediaType is the media type used for the OCI config payload.
+	configMediaType string
+
+	// configJSON references the JSON serialization of the image
+	// configuration.
+	configJSON []byte
+
+	// dependencies is a list of descriptors that gets built by successive
+	// calls to AppendReference.
+	dependencies []distribution.Descriptor
+
+	// artifactType carries the manifest's artifactType, when this
+	// manifest describes an artifact rather than a runnable image.
+	artifactType string
+
+	// subject references another manifest this manifest is attached to,
+	// per the OCI 1.1 artifact/reference model.
+	subject *distribution.Descriptor
+
+	// annotations holds the manifest-level annotations.
+	annotations map[string]string
+}
+
+// BuilderOption configures optional fields of a Builder produced by
+// NewManifestBuilder.
+type BuilderOption func(*builder)
+
+// WithArtifactType sets the artifactType of the manifest under
+// construction, per the OCI 1.1 artifact/reference model.
+func WithArtifactType(artifactType string) BuilderOption {
+	return func(mb *builder) {
+		mb.artifactType = artifactType
+	}
+}
+
+// WithSubject sets the subject of the manifest under construction, marking
+// it as attached to another manifest (for example a signature, SBOM, or
+// provenance attestation attached to an image).
+func WithSubject(subject distribution.Descriptor) BuilderOption {
+	return func(mb *builder) {
+		mb.subject = &subject
+	}
+}
+
+// NewManifestBuilder is used to build new manifests for the current schema
+// version. It takes a BlobService so it can calculate blobsums on the fly.
+func NewManifestBuilder(bs distribution.BlobService, configMediaType string, configJSON []byte, options ...BuilderOption) distribution.ManifestBuilder {
+	mb := &builder{
+		bs:              bs,
+		configMediaType: configMediaType,
+		configJSON:      configJSON,
+	}
+	for _, option := range options {
+		option(mb)
+	}
+	return mb
+}
+
+// Build produces a final manifest from the given references.
+func (mb *builder) Build(ctx context.Context) (distribution.Manifest, error) {
+	m := Manifest{
+		Versioned:    SchemaVersion,
+		ArtifactType: mb.artifactType,
+		Subject:      mb.subject,
+		Annotations:  mb.annotations,
+	}
+
+	configDigest := digest.FromBytes(mb.configJSON)
+
+	var err error
+	m.Config, err = mb.bs.Stat(ctx, configDigest)
+	switch err {
+	case nil:
+		// Override MediaType, since Put always replaces the specified
+		// media type with application/octet-stream in the descriptor it
+		// returns.
+		m.Config.MediaType = mb.configMediaType
+	case distribution.ErrBlobUnknown:
+		// Add config to the blob store
+		m.Config, err = mb.bs.Put(ctx, mb.configMediaType, mb.configJSON)
+		// Override MediaType, since Put always replaces the specified
+		// media type with application/octet-stream in the descriptor it
+		// returns.
+		m.Config.MediaType = mb.configMediaType
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	m.Layers = make([]distribution.Descriptor, len(mb.dependencies))
+	copy(m.Layers, mb.dependencies)
+
+	return FromStruct(m)
+}
+
+// AppendReference adds a reference to the current ManifestBuilder.
+func (mb *builder) AppendReference(d distribution.Describable) error {
+	mb.dependencies = append(mb.dependencies, d.Descriptor())
+	return nil
+}
+
+// References returns the current references added to this builder.
+func (mb *builder) References() []distribution.Descriptor {
+	return mb.dependencies
+}
+
+// SetAnnotations sets the manifest-level annotations that will be included
+// in the built manifest.
+func (mb *builder) SetAnnotations(annotations map[string]string) {
+	mb.annotations = annotations
+}
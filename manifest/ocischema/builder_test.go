@@ -0,0 +1,98 @@
+package ocischema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+)
+
+// fakeBlobService is a minimal distribution.BlobService that only supports
+// the Stat/Put calls the builder makes.
+type fakeBlobService struct {
+	descriptors map[digest.Digest]distribution.Descriptor
+}
+
+func (f *fakeBlobService) Stat(ctx context.Context, dgst digest.Digest) (distribution.Descriptor, error) {
+	if d, ok := f.descriptors[dgst]; ok {
+		return d, nil
+	}
+	return distribution.Descriptor{}, distribution.ErrBlobUnknown
+}
+
+func (f *fakeBlobService) Put(ctx context.Context, mediaType string, p []byte) (distribution.Descriptor, error) {
+	d := distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(p),
+		Size:      int64(len(p)),
+	}
+	if f.descriptors == nil {
+		f.descriptors = make(map[digest.Digest]distribution.Descriptor)
+	}
+	f.descriptors[d.Digest] = d
+	return d, nil
+}
+
+type fakeDescribable struct {
+	descriptor distribution.Descriptor
+}
+
+func (f fakeDescribable) Descriptor() distribution.Descriptor {
+	return f.descriptor
+}
+
+func TestBuilderRoundTripsSubjectArtifactTypeAndAnnotations(t *testing.T) {
+	config := []byte(`{}`)
+	subject := distribution.Descriptor{
+		MediaType: MediaTypeManifest,
+		Digest:    digest.FromBytes([]byte("parent manifest")),
+		Size:      42,
+	}
+	layer := distribution.Descriptor{
+		MediaType: MediaTypeImageLayer,
+		Digest:    digest.FromBytes([]byte("layer")),
+		Size:      7,
+	}
+
+	mb := NewManifestBuilder(&fakeBlobService{}, MediaTypeImageConfig, config,
+		WithSubject(subject),
+		WithArtifactType("application/vnd.in-toto+json"),
+	)
+	mb.SetAnnotations(map[string]string{"foo": "bar"})
+
+	if err := mb.AppendReference(fakeDescribable{layer}); err != nil {
+		t.Fatalf("AppendReference: %v", err)
+	}
+
+	built, err := mb.Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	mediaType, payload, err := built.Payload()
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	if mediaType != MediaTypeManifest {
+		t.Fatalf("unexpected mediaType: %q", mediaType)
+	}
+
+	var deserialized DeserializedManifest
+	if err := deserialized.UnmarshalJSON(payload); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if deserialized.ArtifactType != "application/vnd.in-toto+json" {
+		t.Fatalf("unexpected artifactType: %q", deserialized.ArtifactType)
+	}
+	if deserialized.Subject == nil || deserialized.Subject.Digest != subject.Digest {
+		t.Fatalf("unexpected subject: %+v", deserialized.Subject)
+	}
+	if deserialized.Annotations["foo"] != "bar" {
+		t.Fatalf("unexpected annotations: %+v", deserialized.Annotations)
+	}
+	if len(deserialized.Layers) != 1 || deserialized.Layers[0].Digest != layer.Digest {
+		t.Fatalf("unexpected layers: %+v", deserialized.Layers)
+	}
+}
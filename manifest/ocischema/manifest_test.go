@@ -0,0 +1,48 @@
+package ocischema
+
+import "testing"
+
+func TestUnmarshalJSONRejectsMismatchedMediaType(t *testing.T) {
+	const badMediaType = `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+		"config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:abc", "size": 1},
+		"layers": []
+	}`
+
+	var m DeserializedManifest
+	err := m.UnmarshalJSON([]byte(badMediaType))
+	if err == nil {
+		t.Fatal("expected error unmarshaling a manifest with a mismatched mediaType")
+	}
+	if _, ok := err.(ErrManifestBadMediaType); !ok {
+		t.Fatalf("expected ErrManifestBadMediaType, got %T: %v", err, err)
+	}
+}
+
+func TestUnmarshalJSONAllowsEmptyMediaType(t *testing.T) {
+	const noMediaType = `{
+		"schemaVersion": 2,
+		"config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:abc", "size": 1},
+		"layers": []
+	}`
+
+	var m DeserializedManifest
+	if err := m.UnmarshalJSON([]byte(noMediaType)); err != nil {
+		t.Fatalf("unexpected error unmarshaling a manifest with no mediaType: %v", err)
+	}
+}
+
+func TestUnmarshalJSONAcceptsMatchingMediaType(t *testing.T) {
+	const valid = `{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"config": {"mediaType": "application/vnd.oci.image.config.v1+json", "digest": "sha256:abc", "size": 1},
+		"layers": []
+	}`
+
+	var m DeserializedManifest
+	if err := m.UnmarshalJSON([]byte(valid)); err != nil {
+		t.Fatalf("unexpected error unmarshaling a valid manifest: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package schema2
+
+import (
+	"context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+)
+
+// builder is a type for constructing manifests.
+type builder struct {
+	// bs is a BlobService used to get the blob size info.
+	bs distribution.BlobService
+
+	// configMediaType is the media type used for schema2 config payload.
+	configMediaType string
+
+	// configJSON references the JSON serialization of the image
+	// configuration.
+	configJSON []byte
+
+	// dependencies is a list of descriptors that gets built by successive
+	// calls to AppendReference.
+	dependencies []distribution.Descriptor
+}
+
+// NewManifestBuilder is used to build new manifests for the current schema
+// version. It takes a BlobService so it can calculate blobsums on the fly.
+func NewManifestBuilder(bs distribution.BlobService, configMediaType string, configJSON []byte) distribution.ManifestBuilder {
+	return &builder{
+		bs:              bs,
+		configMediaType: configMediaType,
+		configJSON:      configJSON,
+	}
+}
+
+// Build produces a final manifest from the given references.
+func (mb *builder) Build(ctx context.Context) (distribution.Manifest, error) {
+	m := Manifest{
+		Versioned: SchemaVersion,
+	}
+
+	configDigest := digest.FromBytes(mb.configJSON)
+
+	var err error
+	m.Config, err = mb.bs.Stat(ctx, configDigest)
+	switch err {
+	case nil:
+		// Override MediaType, since Put always replaces the specified
+		// media type with application/octet-stream in the descriptor it
+		// returns.
+		m.Config.MediaType = mb.configMediaType
+	case distribution.ErrBlobUnknown:
+		// Add config to the blob store
+		m.Config, err = mb.bs.Put(ctx, mb.configMediaType, mb.configJSON)
+		// Override MediaType, since Put always replaces the specified
+		// media type with application/octet-stream in the descriptor it
+		// returns.
+		m.Config.MediaType = mb.configMediaType
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	m.Layers = make([]distribution.Descriptor, len(mb.dependencies))
+	copy(m.Layers, mb.dependencies)
+
+	return FromStruct(m)
+}
+
+// AppendReference adds a reference to the current ManifestBuilder.
+func (mb *builder) AppendReference(d distribution.Describable) error {
+	mb.dependencies = append(mb.dependencies, d.Descriptor())
+	return nil
+}
+
+// References returns the current references added to this builder.
+func (mb *builder) References() []distribution.Descriptor {
+	return mb.dependencies
+}
+
+// SetAnnotations is a no-op for schema2: the Docker manifest format has no
+// top-level annotations field.
+func (mb *builder) SetAnnotations(annotations map[string]string) {
+}
@@ -24,17 +24,6 @@ const (
 	// MediaTypeForeignLayer is the mediaType used for layers that must be
 	// downloaded from foreign URLs.
 	MediaTypeForeignLayer = "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip"
-
-	// MediaTypeOCIManifest specifies the mediaType for an image manifest
-	// conforming to the OCI spec.
-	MediaTypeOCIManifest = "application/vnd.oci.image.manifest.v1+json"
-
-	// MediaTypeOCIConfig specifies the mediaType for an image config for an OCI
-	// manifest.
-	MediaTypeOCIConfig = "application/vnd.oci.image.serialization.config.v1+json"
-
-	// MediaTypeOCILayer specifies the mediaType for layer for an OCI manifest.
-	MediaTypeOCILayer = "application/vnd.oci.image.serialization.rootfs.tar.gzip"
 )
 
 var (
@@ -42,34 +31,38 @@ var (
 	// packages version of the manifest.
 	SchemaVersion = manifest.Versioned{
 		SchemaVersion: 2,
-		MediaType:     MediaTypeOCIManifest,
+		MediaType:     MediaTypeManifest,
 	}
 )
 
 func init() {
-	schema2Func := func(mediaType string) func(b []byte) (distribution.Manifest, distribution.Descriptor, error) {
-		return func(b []byte) (distribution.Manifest, distribution.Descriptor, error) {
-
-			m := new(DeserializedManifest)
-			err := m.UnmarshalJSON(b)
-			if err != nil {
-				return nil, distribution.Descriptor{}, err
-			}
-
-			dgst := digest.FromBytes(b)
-			return m, distribution.Descriptor{Digest: dgst, Size: int64(len(b)), MediaType: mediaType}, err
+	schema2Func := func(b []byte) (distribution.Manifest, distribution.Descriptor, error) {
+		m := new(DeserializedManifest)
+		err := m.UnmarshalJSON(b)
+		if err != nil {
+			return nil, distribution.Descriptor{}, err
 		}
+
+		dgst := digest.FromBytes(b)
+		return m, distribution.Descriptor{Digest: dgst, Size: int64(len(b)), MediaType: MediaTypeManifest}, err
 	}
-	err := distribution.RegisterManifestSchema(MediaTypeManifest, schema2Func(MediaTypeManifest))
-	if err != nil {
-		panic(fmt.Sprintf("Unable to register manifest: %s", err))
-	}
-	err = distribution.RegisterManifestSchema(MediaTypeOCIManifest, schema2Func(MediaTypeOCIManifest))
+	err := distribution.RegisterManifestSchema(MediaTypeManifest, schema2Func)
 	if err != nil {
 		panic(fmt.Sprintf("Unable to register manifest: %s", err))
 	}
 }
 
+// ErrManifestBadMediaType is returned when the media type in the manifest
+// itself does not match the media type it was registered under. An empty
+// value is accepted, for payloads that omit mediaType.
+type ErrManifestBadMediaType struct {
+	MediaType string
+}
+
+func (err ErrManifestBadMediaType) Error() string {
+	return fmt.Sprintf("schema2 manifest had unexpected media type: %q", err.MediaType)
+}
+
 // Manifest defines a schema2 manifest.
 type Manifest struct {
 	manifest.Versioned
@@ -107,8 +100,12 @@ func FromStruct(m Manifest) (*DeserializedManifest, error) {
 	var deserialized DeserializedManifest
 	deserialized.Manifest = m
 
-	var err error
-	deserialized.canonical, err = json.MarshalIndent(&m, "", "   ")
+	raw, err := json.Marshal(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	deserialized.canonical, err = manifest.CanonicalJSON(raw)
 	return &deserialized, err
 }
 
@@ -124,6 +121,10 @@ func (m *DeserializedManifest) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	if manifest.MediaType != "" && manifest.MediaType != MediaTypeManifest {
+		return ErrManifestBadMediaType{MediaType: manifest.MediaType}
+	}
+
 	m.Manifest = manifest
 
 	return nil
@@ -142,5 +143,9 @@ func (m *DeserializedManifest) MarshalJSON() ([]byte, error) {
 // Payload returns the raw content of the manifest. The contents can be used to
 // calculate the content identifier.
 func (m DeserializedManifest) Payload() (string, []byte, error) {
-	return m.MediaType, m.canonical, nil
+	mediaType := m.MediaType
+	if mediaType == "" {
+		mediaType = MediaTypeManifest
+	}
+	return mediaType, m.canonical, nil
 }